@@ -0,0 +1,170 @@
+package rotatelogs
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// DropPolicy controls what Write does when the async buffer (enabled
+// via WithAsyncBuffer) is full.
+type DropPolicy int
+
+const (
+	// Block makes Write wait for room in the buffer. This is the
+	// default.
+	Block DropPolicy = iota
+	// DropOldest discards the oldest buffered write to make room for
+	// the incoming one.
+	DropOldest
+	// DropNewest discards the incoming write instead of buffering it.
+	DropNewest
+)
+
+// WithAsyncBuffer creates a new Option that makes Write enqueue onto a
+// bounded channel of the given size instead of writing (and
+// potentially rotating) synchronously. A single background goroutine
+// drains the channel and performs the real writes, removing lock
+// contention and per-write os.Stat cost from the Write hot path.
+func WithAsyncBuffer(size int) Option {
+	return newOption(optkeyAsyncBuffer, size)
+}
+
+// WithDropPolicy creates a new Option that selects what Write does
+// once the async buffer is full. It has no effect unless
+// WithAsyncBuffer is also set.
+func WithDropPolicy(p DropPolicy) Option {
+	return newOption(optkeyDropPolicy, p)
+}
+
+// Stats reports counters accumulated while writing in async mode.
+type Stats struct {
+	// DroppedBytes is the number of bytes discarded because the async
+	// buffer was full and the configured DropPolicy is DropOldest or
+	// DropNewest.
+	DroppedBytes uint64
+}
+
+// Flush blocks until every write enqueued so far has been handed to
+// the underlying file. It is a no-op when WithAsyncBuffer was not
+// used.
+func (rl *RotateLogs) Flush() {
+	if rl.async != nil {
+		rl.async.flush()
+	}
+}
+
+// Stats returns a snapshot of the counters accumulated while writing
+// in async mode. It is always the zero value when WithAsyncBuffer was
+// not used.
+func (rl *RotateLogs) Stats() Stats {
+	if rl.async == nil {
+		return Stats{}
+	}
+	return rl.async.stats()
+}
+
+type asyncItem struct {
+	data    []byte
+	barrier chan struct{}
+}
+
+// asyncWriter decouples Write from the real, synchronous write: items
+// are enqueued onto ch and a single background goroutine drains them
+// in order, calling rl.writeSync for each.
+type asyncWriter struct {
+	ch         chan asyncItem
+	dropPolicy DropPolicy
+	dropped    uint64
+	done       chan struct{}
+	wg         sync.WaitGroup
+}
+
+func newAsyncWriter(rl *RotateLogs, size int, policy DropPolicy) *asyncWriter {
+	aw := &asyncWriter{
+		ch:         make(chan asyncItem, size),
+		dropPolicy: policy,
+		done:       make(chan struct{}),
+	}
+
+	aw.wg.Add(1)
+	go aw.loop(rl)
+
+	return aw
+}
+
+func (aw *asyncWriter) loop(rl *RotateLogs) {
+	defer aw.wg.Done()
+
+	for {
+		select {
+		case item := <-aw.ch:
+			aw.handle(rl, item)
+		case <-aw.done:
+			// drain whatever was left queued before closing
+			for {
+				select {
+				case item := <-aw.ch:
+					aw.handle(rl, item)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (aw *asyncWriter) handle(rl *RotateLogs, item asyncItem) {
+	if item.barrier != nil {
+		close(item.barrier)
+		return
+	}
+	rl.writeSync(item.data)
+}
+
+func (aw *asyncWriter) write(p []byte) {
+	buf := make([]byte, len(p))
+	copy(buf, p)
+	item := asyncItem{data: buf}
+
+	switch aw.dropPolicy {
+	case DropNewest:
+		select {
+		case aw.ch <- item:
+		default:
+			atomic.AddUint64(&aw.dropped, uint64(len(buf)))
+		}
+	case DropOldest:
+		for {
+			select {
+			case aw.ch <- item:
+				return
+			default:
+			}
+			select {
+			case old := <-aw.ch:
+				atomic.AddUint64(&aw.dropped, uint64(len(old.data)))
+			default:
+			}
+		}
+	default: // Block
+		aw.ch <- item
+	}
+}
+
+// flush enqueues a barrier and waits for the drain goroutine to reach
+// it, guaranteeing every write enqueued before this call has been
+// handed to writeSync.
+func (aw *asyncWriter) flush() {
+	barrier := make(chan struct{})
+	aw.ch <- asyncItem{barrier: barrier}
+	<-barrier
+}
+
+func (aw *asyncWriter) close() {
+	close(aw.done)
+	aw.wg.Wait()
+}
+
+func (aw *asyncWriter) stats() Stats {
+	return Stats{DroppedBytes: atomic.LoadUint64(&aw.dropped)}
+}
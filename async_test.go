@@ -0,0 +1,73 @@
+package rotatelogs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAsyncWriterDropNewestDiscardsIncomingWhenFull(t *testing.T) {
+	aw := &asyncWriter{ch: make(chan asyncItem, 2), dropPolicy: DropNewest}
+
+	aw.write([]byte("a"))
+	aw.write([]byte("b"))
+	aw.write([]byte("c")) // buffer full, should be discarded
+
+	if got := aw.stats().DroppedBytes; got != 1 {
+		t.Fatalf("DroppedBytes = %d, want 1", got)
+	}
+
+	if got := string((<-aw.ch).data); got != "a" {
+		t.Fatalf("first queued item = %q, want %q", got, "a")
+	}
+	if got := string((<-aw.ch).data); got != "b" {
+		t.Fatalf("second queued item = %q, want %q", got, "b")
+	}
+	if len(aw.ch) != 0 {
+		t.Fatalf("expected buffer to be empty, got %d items", len(aw.ch))
+	}
+}
+
+func TestAsyncWriterDropOldestEvictsOldestWhenFull(t *testing.T) {
+	aw := &asyncWriter{ch: make(chan asyncItem, 2), dropPolicy: DropOldest}
+
+	aw.write([]byte("a"))
+	aw.write([]byte("b"))
+	aw.write([]byte("c")) // should evict "a" to make room
+
+	if got := aw.stats().DroppedBytes; got != 1 {
+		t.Fatalf("DroppedBytes = %d, want 1", got)
+	}
+
+	if got := string((<-aw.ch).data); got != "b" {
+		t.Fatalf("first queued item = %q, want %q", got, "b")
+	}
+	if got := string((<-aw.ch).data); got != "c" {
+		t.Fatalf("second queued item = %q, want %q", got, "c")
+	}
+}
+
+func TestAsyncWriterBlockWaitsForRoom(t *testing.T) {
+	aw := &asyncWriter{ch: make(chan asyncItem, 1), dropPolicy: Block}
+
+	aw.write([]byte("a")) // fills the only slot
+
+	done := make(chan struct{})
+	go func() {
+		aw.write([]byte("b"))
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("write should have blocked while the buffer was full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	<-aw.ch // drain "a", freeing a slot
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the blocked write to complete")
+	}
+}
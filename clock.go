@@ -0,0 +1,20 @@
+package rotatelogs
+
+import "time"
+
+// Clock is the interface used by RotateLogs to determine the current
+// time. It is satisfied by clockFn, which allows us to special-case
+// UTC vs local time without keeping a boolean flag around.
+type Clock interface {
+	Now() time.Time
+}
+
+type clockFn func() time.Time
+
+// UTC is an object satisfying the Clock interface, which returns the
+// current time in UTC
+var UTC = clockFn(func() time.Time { return time.Now().UTC() })
+
+// Local is an object satisfying the Clock interface, which returns the
+// current time in the local timezone
+var Local = clockFn(time.Now)
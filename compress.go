@@ -0,0 +1,161 @@
+package rotatelogs
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/pkg/errors"
+)
+
+// CompressAlgorithm identifies the compression scheme applied to a
+// rotated file.
+type CompressAlgorithm string
+
+const (
+	// CompressNone disables compression of rotated files. This is
+	// the default.
+	CompressNone CompressAlgorithm = ""
+	// CompressGzip compresses rotated files with gzip, appending a
+	// ".gz" suffix to the rotated file name.
+	CompressGzip CompressAlgorithm = "gzip"
+	// CompressZstd compresses rotated files with zstd, appending a
+	// ".zst" suffix to the rotated file name.
+	CompressZstd CompressAlgorithm = "zstd"
+)
+
+// suffix returns the file extension appended to a file once it has
+// been compressed with this algorithm.
+func (a CompressAlgorithm) suffix() string {
+	switch a {
+	case CompressGzip:
+		return ".gz"
+	case CompressZstd:
+		return ".zst"
+	default:
+		return ""
+	}
+}
+
+// WithCompress creates a new Option that compresses a file in place
+// once it has been rotated out (whether by time or by size). The
+// original, uncompressed file is removed once compression succeeds.
+// Compression runs in its own goroutine and never blocks Write.
+func WithCompress(algorithm CompressAlgorithm) Option {
+	return newOption(optkeyCompress, algorithm)
+}
+
+// WithCompressAfter creates a new Option that delays compression of a
+// just-rotated file by d. This gives external log shippers a grace
+// period to finish reading the file under its original name.
+func WithCompressAfter(d time.Duration) Option {
+	return newOption(optkeyCompressAfter, d)
+}
+
+// FileCompressedEvent is sent to a Handler once a rotated file has
+// finished being compressed.
+type FileCompressedEvent struct {
+	src string
+	dst string
+}
+
+func (e *FileCompressedEvent) Type() EventType {
+	return FileCompressedEventType
+}
+
+// SourceFile returns the name of the (now removed) file that was
+// compressed.
+func (e *FileCompressedEvent) SourceFile() string {
+	return e.src
+}
+
+// CompressedFile returns the name of the resulting compressed file.
+func (e *FileCompressedEvent) CompressedFile() string {
+	return e.dst
+}
+
+// purgeGlobPatterns returns the set of glob patterns that rotateNolock
+// should search when looking for files to purge. When compression is
+// enabled this includes the compressed suffix, so already-compressed
+// files are still found and aged out.
+func (rl *RotateLogs) purgeGlobPatterns() []string {
+	patterns := []string{rl.globPattern}
+	if suffix := rl.compressAlgorithm.suffix(); suffix != "" {
+		patterns = append(patterns, rl.globPattern+suffix)
+	}
+	return patterns
+}
+
+// compress compresses src in place, writing to a ".tmp" file first and
+// renaming it over the final name so a crash mid-compression never
+// leaves behind a file that looks finished but isn't. src is removed
+// only after the compressed copy is safely on disk.
+func (rl *RotateLogs) compress(src string) {
+	suffix := rl.compressAlgorithm.suffix()
+	if suffix == "" {
+		return
+	}
+
+	if rl.compressAfter > 0 {
+		time.Sleep(rl.compressAfter)
+	}
+
+	dst := src + suffix
+	tmp := dst + ".tmp"
+
+	if err := compressFile(src, tmp, rl.compressAlgorithm); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", errors.Wrapf(err, `failed to compress %s`, src).Error())
+		os.Remove(tmp)
+		return
+	}
+
+	if err := os.Rename(tmp, dst); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", errors.Wrapf(err, `failed to rename %s to %s`, tmp, dst).Error())
+		os.Remove(tmp)
+		return
+	}
+
+	os.Remove(src)
+
+	if h := rl.eventHandler; h != nil {
+		h.Handle(&FileCompressedEvent{src: src, dst: dst})
+	}
+}
+
+func compressFile(src, dst string, algorithm CompressAlgorithm) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return errors.Wrapf(err, `failed to open %s`, src)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return errors.Wrapf(err, `failed to create %s`, dst)
+	}
+	defer out.Close()
+
+	var w io.WriteCloser
+	switch algorithm {
+	case CompressGzip:
+		w = gzip.NewWriter(out)
+	case CompressZstd:
+		zw, err := zstd.NewWriter(out)
+		if err != nil {
+			return errors.Wrap(err, `failed to create zstd writer`)
+		}
+		w = zw
+	default:
+		return errors.Errorf(`unknown compression algorithm %q`, algorithm)
+	}
+
+	if _, err := io.Copy(w, in); err != nil {
+		w.Close()
+		return errors.Wrapf(err, `failed to compress %s`, src)
+	}
+
+	return w.Close()
+}
@@ -0,0 +1,62 @@
+package rotatelogs
+
+// EventType identifies the kind of Event a Handler is being notified
+// about.
+type EventType int
+
+const (
+	InvalidEventType EventType = iota
+	// FileRotatedEventType is sent when a log file has just been
+	// rotated to a new name.
+	FileRotatedEventType
+	// FileCompressedEventType is sent when a rotated-out file has
+	// finished being compressed.
+	FileCompressedEventType
+	// PurgedEventType is sent each time an outdated file is removed
+	// during purge.
+	PurgedEventType
+)
+
+// Event is the common interface implemented by every event that
+// RotateLogs may dispatch to a Handler.
+type Event interface {
+	Type() EventType
+}
+
+// Handler is the interface that wraps the Handle method. Handle is
+// called (in its own goroutine) whenever a RotateLogs-related event
+// occurs.
+type Handler interface {
+	Handle(Event)
+}
+
+// HandlerFunc is an adapter that allows ordinary functions to be used
+// as a Handler.
+type HandlerFunc func(Event)
+
+// Handle calls f(e)
+func (f HandlerFunc) Handle(e Event) {
+	f(e)
+}
+
+// FileRotatedEvent is sent to a Handler when a log file gets rotated.
+// It carries both the previous and the newly created file names.
+type FileRotatedEvent struct {
+	prev    string
+	current string
+}
+
+func (e *FileRotatedEvent) Type() EventType {
+	return FileRotatedEventType
+}
+
+// PreviousFile returns the name of the file used before rotation.
+// It may be empty if this is the first file ever used.
+func (e *FileRotatedEvent) PreviousFile() string {
+	return e.prev
+}
+
+// CurrentFile returns the name of the file used after rotation.
+func (e *FileRotatedEvent) CurrentFile() string {
+	return e.current
+}
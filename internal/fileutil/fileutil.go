@@ -0,0 +1,44 @@
+// Package fileutil houses small filesystem helpers shared by the
+// rotatelogs package. It is kept separate (and dependency-free with
+// respect to the parent package) so it can be unit tested in isolation.
+package fileutil
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	strftime "github.com/lestrrat-go/strftime"
+	"github.com/pkg/errors"
+)
+
+// Clocker is satisfied by anything that can report the current time.
+// It mirrors rotatelogs.Clock without importing that package, which
+// would otherwise create an import cycle.
+type Clocker interface {
+	Now() time.Time
+}
+
+// GenerateFn executes the strftime pattern against the current time
+// (as reported by clock), truncating to rotationTime when it is set.
+func GenerateFn(pattern *strftime.Strftime, clock Clocker, rotationTime time.Duration) string {
+	now := clock.Now()
+	if rotationTime > 0 {
+		now = now.Truncate(rotationTime)
+	}
+	return pattern.FormatString(now)
+}
+
+// CreateFile creates (or opens for append) the file at filename,
+// creating any missing parent directories along the way.
+func CreateFile(filename string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return nil, errors.Wrapf(err, `failed to create directory %s`, filepath.Dir(filename))
+	}
+
+	fh, err := os.OpenFile(filename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+	if err != nil {
+		return nil, errors.Wrapf(err, `failed to open file %s`, filename)
+	}
+	return fh, nil
+}
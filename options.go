@@ -0,0 +1,100 @@
+package rotatelogs
+
+import "time"
+
+// optkey is the type of the internal key used to identify individual
+// options passed to New. It is unexported so that only this package
+// may create valid Option values.
+type optkey string
+
+const (
+	optkeyClock            = optkey("clock")
+	optkeyLinkName         = optkey("link-name")
+	optkeyMaxAge           = optkey("max-age")
+	optkeyRotationTime     = optkey("rotation-time")
+	optkeyRotationSize     = optkey("rotation-size")
+	optkeyRotationCount    = optkey("rotation-count")
+	optkeyHandler          = optkey("handler")
+	optkeyForceNewFile     = optkey("force-new-file")
+	optkeyCompress         = optkey("compress")
+	optkeyCompressAfter    = optkey("compress-after")
+	optkeyRotateRule       = optkey("rotate-rule")
+	optkeyPurgeConcurrency = optkey("purge-concurrency")
+	optkeyRotateMode       = optkey("rotate-mode")
+	optkeyMaxBackups       = optkey("max-backups")
+	optkeyAsyncBuffer      = optkey("async-buffer")
+	optkeyDropPolicy       = optkey("drop-policy")
+)
+
+// Option is used to pass optional arguments to New
+type Option interface {
+	Name() optkey
+	Value() interface{}
+}
+
+type option struct {
+	name  optkey
+	value interface{}
+}
+
+func newOption(name optkey, value interface{}) Option {
+	return &option{name: name, value: value}
+}
+
+func (o *option) Name() optkey       { return o.name }
+func (o *option) Value() interface{} { return o.value }
+
+// WithClock creates a new Option that sets a clock that the
+// RotateLogs object will use to determine the current time.
+func WithClock(c Clock) Option {
+	return newOption(optkeyClock, c)
+}
+
+// WithLinkName creates a new Option that sets the symbolic link name
+// that gets pointed to the current file name being used.
+func WithLinkName(s string) Option {
+	return newOption(optkeyLinkName, s)
+}
+
+// WithMaxAge creates a new Option that sets the max age of a log file
+// before it gets purged from the file system. It cannot be combined
+// with WithRotateRule, which owns purge policy on its own.
+func WithMaxAge(d time.Duration) Option {
+	return newOption(optkeyMaxAge, d)
+}
+
+// WithRotationTime creates a new Option that sets the time between
+// rotations.
+func WithRotationTime(d time.Duration) Option {
+	return newOption(optkeyRotationTime, d)
+}
+
+// WithRotationSize creates a new Option that sets the log file to be
+// rotated once it exceeds the given size in bytes.
+func WithRotationSize(s int64) Option {
+	return newOption(optkeyRotationSize, s)
+}
+
+// WithRotationCount creates a new Option that sets the number of
+// files to keep before the oldest one gets purged from the file
+// system. It cannot be combined with WithRotateRule, which owns purge
+// policy on its own.
+func WithRotationCount(n uint) Option {
+	return newOption(optkeyRotationCount, n)
+}
+
+// WithHandler creates a new Option that specifies the event handler
+// that gets notified when an event occurs: FileRotatedEvent on
+// rotation, FileCompressedEvent once a rotated file is compressed, and
+// PurgedEvent for each file removed during purge.
+func WithHandler(h Handler) Option {
+	return newOption(optkeyHandler, h)
+}
+
+// WithForceNewFile creates a new Option that sets the forceNewFile
+// flag on the RotateLogs object. The flag is stored but not consulted
+// when choosing a file: the first write after New() still reuses an
+// existing file that matches the current pattern.
+func WithForceNewFile() Option {
+	return newOption(optkeyForceNewFile, true)
+}
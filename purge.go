@@ -0,0 +1,58 @@
+package rotatelogs
+
+import (
+	"os"
+	"sync"
+)
+
+// WithPurgeConcurrency creates a new Option that limits how many
+// outdated files rotateNolock will unlink in parallel. When this option
+// is absent, or n is 0 or less, files are purged one at a time.
+func WithPurgeConcurrency(n int) Option {
+	return newOption(optkeyPurgeConcurrency, n)
+}
+
+// PurgedEvent is sent to a Handler each time an outdated file is
+// removed during purge.
+type PurgedEvent struct {
+	file string
+}
+
+func (e *PurgedEvent) Type() EventType {
+	return PurgedEventType
+}
+
+// File returns the path of the file that was removed.
+func (e *PurgedEvent) File() string {
+	return e.file
+}
+
+// purgeFiles removes every path in paths, running at most
+// rl.purgeConcurrency removals at a time. It is meant to be invoked
+// in its own goroutine so that Write/Rotate never block on purge.
+func (rl *RotateLogs) purgeFiles(paths []string) {
+	concurrency := rl.purgeConcurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for _, path := range paths {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(path string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			if err := os.Remove(path); err != nil {
+				return
+			}
+
+			if h := rl.eventHandler; h != nil {
+				h.Handle(&PurgedEvent{file: path})
+			}
+		}(path)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,105 @@
+package rotatelogs
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestPurgeFilesRemovesAllAndEmitsEvents(t *testing.T) {
+	dir := t.TempDir()
+
+	var paths []string
+	for i := 0; i < 5; i++ {
+		name := filepath.Join(dir, string(rune('a'+i))+".log")
+		if err := os.WriteFile(name, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, name)
+	}
+
+	var mu sync.Mutex
+	var purged []string
+	rl := &RotateLogs{
+		purgeConcurrency: 2,
+		eventHandler: HandlerFunc(func(e Event) {
+			pe, ok := e.(*PurgedEvent)
+			if !ok {
+				return
+			}
+			mu.Lock()
+			purged = append(purged, pe.File())
+			mu.Unlock()
+		}),
+	}
+
+	rl.purgeFiles(paths)
+
+	for _, name := range paths {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed, stat err = %v", name, err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(purged) != len(paths) {
+		t.Fatalf("got %d PurgedEvents, want %d", len(purged), len(paths))
+	}
+}
+
+func TestRotateNolockRemovesAllExpiredFiles(t *testing.T) {
+	dir := t.TempDir()
+
+	var names []string
+	for i := 0; i < 4; i++ {
+		name := filepath.Join(dir, "app."+string(rune('a'+i))+".log")
+		if err := os.WriteFile(name, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		old := time.Now().Add(-48 * time.Hour)
+		if err := os.Chtimes(name, old, old); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+
+	done := make(chan struct{})
+	var mu sync.Mutex
+	var count int
+	rl := &RotateLogs{
+		clock:       Local,
+		globPattern: filepath.Join(dir, "app.*.log"),
+		maxAge:      time.Hour,
+		eventHandler: HandlerFunc(func(e Event) {
+			if _, ok := e.(*PurgedEvent); !ok {
+				return
+			}
+			mu.Lock()
+			count++
+			n := count
+			mu.Unlock()
+			if n == len(names) {
+				close(done)
+			}
+		}),
+	}
+
+	if err := rl.rotateNolock(filepath.Join(dir, "app.new.log")); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for all expired files to be purged")
+	}
+
+	for _, name := range names {
+		if _, err := os.Stat(name); !os.IsNotExist(err) {
+			t.Fatalf("expected %s to be removed, stat err = %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package rotatelogs
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/etsme-com/file-rotatelogs/internal/fileutil"
+	"github.com/pkg/errors"
+)
+
+// RotateMode selects how RotateLogs names files once they rotate.
+type RotateMode int
+
+const (
+	// ModeCreate is the default behavior: strftime-pattern file names,
+	// with a ".rotation.N" suffix appended on same-period collisions
+	// or size-triggered rotation.
+	ModeCreate RotateMode = iota
+
+	// ModeRename always writes to the same fixed path. On rotation
+	// that path is renamed out of the way (foo.log -> foo.log.1,
+	// shifting existing backups up to MaxBackups), lumberjack-style.
+	// This keeps the active file name stable, which external log
+	// shippers that tail a fixed path rely on.
+	ModeRename
+)
+
+// WithRotateMode creates a new Option that selects the file naming
+// scheme used on rotation. The default is ModeCreate.
+func WithRotateMode(mode RotateMode) Option {
+	return newOption(optkeyRotateMode, mode)
+}
+
+// WithMaxBackups creates a new Option that, under ModeRename, caps how
+// many numbered backups (foo.log.1, foo.log.2, ...) are kept on disk.
+// A value of 0 means unlimited (MaxAge/RotationCount purge still
+// applies on top of this).
+func WithMaxBackups(n uint) Option {
+	return newOption(optkeyMaxBackups, n)
+}
+
+// getWriterNolockRename is the ModeRename counterpart to
+// getWriterNolock: the active file name never changes, so rotation is
+// purely a size check followed by a rename-and-shift of backups;
+// forceRotate (set by Rotate) skips the size check. It must be called
+// with rl.mutex already held.
+func (rl *RotateLogs) getWriterNolockRename(forceRotate bool) (io.Writer, error) {
+	rotated := false
+
+	if rl.outFh != nil {
+		if !forceRotate {
+			if rl.rotationSize <= 0 {
+				return rl.outFh, nil
+			}
+
+			fi, err := rl.outFh.Stat()
+			if err == nil && fi.Size() < rl.rotationSize {
+				return rl.outFh, nil
+			}
+		}
+
+		if err := rl.shiftBackups(); err != nil {
+			return nil, errors.Wrap(err, "failed to rotate")
+		}
+		rotated = true
+	}
+
+	fh, err := fileutil.CreateFile(rl.activeFn)
+	if err != nil {
+		return nil, errors.Wrapf(err, `failed to create a new file %v`, rl.activeFn)
+	}
+
+	rl.outFh = fh
+	rl.curFn = rl.activeFn
+
+	if h := rl.eventHandler; h != nil {
+		go h.Handle(&FileRotatedEvent{
+			prev:    rl.activeFn,
+			current: rl.activeFn,
+		})
+	}
+
+	// rotateNolock (re)points linkName at rl.activeFn and purges
+	// backups matched by globPattern ("activeFn.*") according to
+	// MaxAge/RotationCount, same as the ModeCreate path.
+	if rotated {
+		if err := rl.rotateNolock(rl.activeFn); err != nil {
+			fmt.Fprintf(os.Stderr, "%s\n", errors.Wrap(err, "failed to rotate").Error())
+		}
+
+		// shiftBackups just moved the previously-active file to
+		// activeFn+".1"; hand it to the background compressor, same
+		// as the ModeCreate path does for the file it just rotated
+		// out of.
+		if rl.compressAlgorithm != CompressNone {
+			go rl.compress(fmt.Sprintf("%s.1", rl.activeFn))
+		}
+	}
+
+	return fh, nil
+}
+
+// shiftBackups renames rl.activeFn out of the way, shifting any
+// existing numbered backups up by one slot (foo.log.1 -> foo.log.2,
+// etc), and removes whatever falls off the end of MaxBackups.
+//
+// The scan is bounded by how many numbered backups actually exist on
+// disk (it stops at the first missing ".N"), not by MaxBackups: when
+// MaxBackups is left at its default of 0 ("unlimited") there is no
+// synthetic upper bound to count down from.
+func (rl *RotateLogs) shiftBackups() error {
+	if rl.outFh != nil {
+		rl.outFh.Close()
+		rl.outFh = nil
+	}
+
+	n := 0
+	for {
+		if _, err := os.Stat(fmt.Sprintf("%s.%d", rl.activeFn, n+1)); err != nil {
+			break
+		}
+		n++
+	}
+
+	max := int(rl.maxBackups)
+
+	for i := n; i >= 1; i-- {
+		src := fmt.Sprintf("%s.%d", rl.activeFn, i)
+
+		if max > 0 && i+1 > max {
+			os.Remove(src)
+			continue
+		}
+
+		dst := fmt.Sprintf("%s.%d", rl.activeFn, i+1)
+		if err := os.Rename(src, dst); err != nil {
+			return errors.Wrapf(err, `failed to rename %s to %s`, src, dst)
+		}
+	}
+
+	if _, err := os.Stat(rl.activeFn); err == nil {
+		dst := fmt.Sprintf("%s.1", rl.activeFn)
+		if err := os.Rename(rl.activeFn, dst); err != nil {
+			return errors.Wrapf(err, `failed to rename %s to %s`, rl.activeFn, dst)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package rotatelogs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("ReadFile(%s): %v", name, err)
+	}
+	return string(b)
+}
+
+func TestShiftBackupsPrunesPastMaxBackups(t *testing.T) {
+	dir := t.TempDir()
+	activeFn := filepath.Join(dir, "app.log")
+
+	if err := os.WriteFile(activeFn, []byte("gen0"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(activeFn+".1", []byte("gen1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(activeFn+".2", []byte("gen2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rl := &RotateLogs{activeFn: activeFn, maxBackups: 2}
+
+	if err := rl.shiftBackups(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(activeFn); !os.IsNotExist(err) {
+		t.Fatalf("expected %s to be renamed away, stat err = %v", activeFn, err)
+	}
+	if _, err := os.Stat(activeFn + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("expected %s.3 to not exist (past MaxBackups)", activeFn)
+	}
+
+	if got := readFile(t, activeFn+".1"); got != "gen0" {
+		t.Fatalf("%s.1 = %q, want %q", activeFn, got, "gen0")
+	}
+	if got := readFile(t, activeFn+".2"); got != "gen1" {
+		t.Fatalf("%s.2 = %q, want %q", activeFn, got, "gen1")
+	}
+}
+
+func TestShiftBackupsUnlimitedKeepsEverything(t *testing.T) {
+	dir := t.TempDir()
+	activeFn := filepath.Join(dir, "app.log")
+
+	if err := os.WriteFile(activeFn, []byte("gen0"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(activeFn+".1", []byte("gen1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rl := &RotateLogs{activeFn: activeFn, maxBackups: 0}
+
+	if err := rl.shiftBackups(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := readFile(t, activeFn+".1"); got != "gen0" {
+		t.Fatalf("%s.1 = %q, want %q", activeFn, got, "gen0")
+	}
+	if got := readFile(t, activeFn+".2"); got != "gen1" {
+		t.Fatalf("%s.2 = %q, want %q", activeFn, got, "gen1")
+	}
+}
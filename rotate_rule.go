@@ -0,0 +1,315 @@
+package rotatelogs
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/etsme-com/file-rotatelogs/internal/fileutil"
+	"github.com/pkg/errors"
+)
+
+// RotateRule decides when a RotateLogs object should roll over to a
+// new file, what that file should be named, and which existing files
+// are outdated and may be purged. Built-in rules cover the common
+// time- and size-based policies (DailyRule, HourlyRule, SizeRule);
+// CompositeRule ORs several rules together. Register a custom rule
+// with WithRotateRule.
+//
+// When a RotateRule is set, it takes over the rotation decision that
+// getWriterNolock would otherwise make from the strftime pattern and
+// rotation size/count, so custom rules are free to name files however
+// they like. This is an additive, opt-in path: the legacy
+// strftime/".rotation.N"/size logic in getWriterNolock is unchanged and
+// still runs whenever no RotateRule is configured, so the two decision
+// paths exist side by side rather than one being built on the other.
+type RotateRule interface {
+	// ShallRotate reports whether the active file, whose current size
+	// is currentSize, should be rotated right now.
+	ShallRotate(currentSize int64) bool
+
+	// NextFileName returns the name the new active file should have.
+	NextFileName() string
+
+	// MarkRotated is called once rotation has completed successfully
+	// so the rule can reset whatever state it tracks (e.g. "rotated
+	// at").
+	MarkRotated()
+
+	// OutdatedFiles returns, out of the files matched by glob, those
+	// this rule considers eligible for purge.
+	OutdatedFiles(glob string) []string
+}
+
+// WithRotateRule creates a new Option that hands rotation policy over
+// to r, bypassing the built-in strftime/size-based decision. Purge
+// policy is handed over too, via r.OutdatedFiles, so WithRotateRule
+// cannot be combined with WithMaxAge or WithRotationCount: New returns
+// an error if both are given.
+func WithRotateRule(r RotateRule) Option {
+	return newOption(optkeyRotateRule, r)
+}
+
+// DailyRule rotates baseName once the calendar day (in the rule's
+// clock) changes, producing names of the form "baseName.20060102".
+type DailyRule struct {
+	baseName string
+	clock    Clock
+	rotated  time.Time
+}
+
+// NewDailyRule creates a DailyRule that rotates baseName once a day,
+// using clock to determine the current day.
+func NewDailyRule(baseName string, clock Clock) *DailyRule {
+	return &DailyRule{baseName: baseName, clock: clock, rotated: clock.Now()}
+}
+
+func (r *DailyRule) ShallRotate(int64) bool {
+	return r.clock.Now().YearDay() != r.rotated.YearDay() || r.clock.Now().Year() != r.rotated.Year()
+}
+
+func (r *DailyRule) NextFileName() string {
+	return fmt.Sprintf("%s.%s", r.baseName, r.clock.Now().Format("20060102"))
+}
+
+func (r *DailyRule) MarkRotated() {
+	r.rotated = r.clock.Now()
+}
+
+func (r *DailyRule) OutdatedFiles(glob string) []string {
+	return outdatedByAge(glob, 7*24*time.Hour, r.clock)
+}
+
+// HourlyRule rotates baseName once the hour (in the rule's clock)
+// changes, producing names of the form "baseName.2006010215".
+type HourlyRule struct {
+	baseName string
+	clock    Clock
+	rotated  time.Time
+}
+
+// NewHourlyRule creates an HourlyRule that rotates baseName once an
+// hour, using clock to determine the current hour.
+func NewHourlyRule(baseName string, clock Clock) *HourlyRule {
+	return &HourlyRule{baseName: baseName, clock: clock, rotated: clock.Now()}
+}
+
+func (r *HourlyRule) ShallRotate(int64) bool {
+	return !r.clock.Now().Truncate(time.Hour).Equal(r.rotated.Truncate(time.Hour))
+}
+
+func (r *HourlyRule) NextFileName() string {
+	return fmt.Sprintf("%s.%s", r.baseName, r.clock.Now().Format("2006010215"))
+}
+
+func (r *HourlyRule) MarkRotated() {
+	r.rotated = r.clock.Now()
+}
+
+func (r *HourlyRule) OutdatedFiles(glob string) []string {
+	return outdatedByAge(glob, 7*24*time.Hour, r.clock)
+}
+
+// SizeRule rotates baseName once it grows past maxSize bytes,
+// producing names of the form "baseName.1", "baseName.2", and so on.
+type SizeRule struct {
+	baseName string
+	maxSize  int64
+	seq      int
+}
+
+// NewSizeRule creates a SizeRule that rotates baseName once it
+// exceeds maxSize bytes. If numbered backups (baseName.1, baseName.2,
+// ...) already exist on disk -- e.g. because the process restarted --
+// the sequence picks up after the highest one found, so the next
+// rotation never lands inside an existing backup.
+func NewSizeRule(baseName string, maxSize int64) *SizeRule {
+	return &SizeRule{baseName: baseName, maxSize: maxSize, seq: highestSizeRuleSeq(baseName)}
+}
+
+// highestSizeRuleSeq scans for baseName.N files already on disk and
+// returns the highest N found, or 0 if there are none.
+func highestSizeRuleSeq(baseName string) int {
+	matches, err := filepath.Glob(baseName + ".*")
+	if err != nil {
+		return 0
+	}
+
+	max := 0
+	for _, path := range matches {
+		idx := strings.LastIndex(path, ".")
+		n, err := strconv.Atoi(path[idx+1:])
+		if err != nil {
+			continue
+		}
+		if n > max {
+			max = n
+		}
+	}
+	return max
+}
+
+func (r *SizeRule) ShallRotate(currentSize int64) bool {
+	return r.maxSize > 0 && currentSize >= r.maxSize
+}
+
+func (r *SizeRule) NextFileName() string {
+	return fmt.Sprintf("%s.%d", r.baseName, r.seq+1)
+}
+
+func (r *SizeRule) MarkRotated() {
+	r.seq++
+}
+
+// OutdatedFiles always returns nil: size alone says nothing about how
+// long a rotated file should be kept, so SizeRule defines no purge
+// policy of its own. Compose it with a time-based rule (via
+// CompositeRule) to get both a size trigger and age-based purge.
+func (r *SizeRule) OutdatedFiles(glob string) []string {
+	return nil
+}
+
+// CompositeRule rotates whenever any one of its member rules would,
+// and reports a file as outdated when any member rule does.
+type CompositeRule struct {
+	rules []RotateRule
+
+	// winner is the index into rules of whichever rule's ShallRotate
+	// last returned true, or -1 if none did. NextFileName must reuse
+	// this instead of re-evaluating ShallRotate itself: it has no
+	// access to the real current file size, so a second evaluation
+	// (e.g. against a fabricated size of 0) can pick a different,
+	// wrong rule than the one that actually fired.
+	winner int
+}
+
+// NewCompositeRule ORs together the given rules: rotation happens as
+// soon as any one of them says to, and NextFileName delegates to
+// whichever rule actually fired.
+func NewCompositeRule(rules ...RotateRule) *CompositeRule {
+	return &CompositeRule{rules: rules, winner: -1}
+}
+
+func (r *CompositeRule) ShallRotate(currentSize int64) bool {
+	for i, rule := range r.rules {
+		if rule.ShallRotate(currentSize) {
+			r.winner = i
+			return true
+		}
+	}
+	r.winner = -1
+	return false
+}
+
+func (r *CompositeRule) NextFileName() string {
+	if r.winner >= 0 {
+		return r.rules[r.winner].NextFileName()
+	}
+	return r.rules[0].NextFileName()
+}
+
+func (r *CompositeRule) MarkRotated() {
+	for _, rule := range r.rules {
+		rule.MarkRotated()
+	}
+}
+
+func (r *CompositeRule) OutdatedFiles(glob string) []string {
+	seen := make(map[string]struct{})
+	var outdated []string
+	for _, rule := range r.rules {
+		for _, path := range rule.OutdatedFiles(glob) {
+			if _, ok := seen[path]; ok {
+				continue
+			}
+			seen[path] = struct{}{}
+			outdated = append(outdated, path)
+		}
+	}
+	return outdated
+}
+
+// getWriterNolockWithRule is the orchestrator used when a RotateRule
+// has been registered via WithRotateRule: it asks the rule whether to
+// rotate and what to name the result, instead of deriving that from
+// the strftime pattern and rotation size/count directly. It must be
+// called with rl.mutex already held.
+func (rl *RotateLogs) getWriterNolockWithRule(bailOnRotateFail, forceRotate bool) (io.Writer, error) {
+	previousFn := rl.curFn
+
+	var currentSize int64
+	if fi, err := os.Stat(rl.curFn); err == nil {
+		currentSize = fi.Size()
+	}
+
+	if rl.curFn != "" && !forceRotate && !rl.rotateRule.ShallRotate(currentSize) {
+		return rl.outFh, nil
+	}
+
+	filename := rl.rotateRule.NextFileName()
+
+	fh, err := fileutil.CreateFile(filename)
+	if err != nil {
+		return nil, errors.Wrapf(err, `failed to create a new file %v`, filename)
+	}
+
+	if err := rl.rotateNolock(filename); err != nil {
+		err = errors.Wrap(err, "failed to rotate")
+		if bailOnRotateFail {
+			if fh != nil {
+				fh.Close()
+			}
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
+	}
+
+	if rl.outFh != nil {
+		rl.outFh.Close()
+	}
+	rl.outFh = fh
+	rl.curFn = filename
+	rl.rotateRule.MarkRotated()
+
+	if h := rl.eventHandler; h != nil {
+		go h.Handle(&FileRotatedEvent{
+			prev:    previousFn,
+			current: filename,
+		})
+	}
+
+	if previousFn != "" && previousFn != filename && rl.compressAlgorithm != CompressNone {
+		go rl.compress(previousFn)
+	}
+
+	return fh, nil
+}
+
+// outdatedByAge is a small helper shared by the time-based rules: it
+// globs for matches and returns those older than maxAge.
+func outdatedByAge(glob string, maxAge time.Duration, clock Clock) []string {
+	matches, err := filepath.Glob(glob)
+	if err != nil {
+		return nil
+	}
+
+	sort.Strings(matches)
+
+	cutoff := clock.Now().Add(-1 * maxAge)
+	var outdated []string
+	for _, path := range matches {
+		fi, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if fi.ModTime().Before(cutoff) {
+			outdated = append(outdated, path)
+		}
+	}
+	return outdated
+}
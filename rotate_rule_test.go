@@ -0,0 +1,70 @@
+package rotatelogs
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+type stubRule struct {
+	name    string
+	rotate  bool
+	rotated int
+}
+
+func (r *stubRule) ShallRotate(int64) bool { return r.rotate }
+func (r *stubRule) NextFileName() string   { return r.name }
+func (r *stubRule) MarkRotated()           { r.rotated++ }
+func (r *stubRule) OutdatedFiles(string) []string {
+	return nil
+}
+
+func TestCompositeRuleNextFileNameMatchesWinningRule(t *testing.T) {
+	daily := &stubRule{name: "daily"}
+	size := &stubRule{name: "size", rotate: true}
+
+	composite := NewCompositeRule(daily, size)
+
+	if !composite.ShallRotate(999) {
+		t.Fatal("expected ShallRotate to report a rotation is due")
+	}
+
+	if got := composite.NextFileName(); got != "size" {
+		t.Fatalf("NextFileName() = %q, want %q (the rule that actually fired)", got, "size")
+	}
+}
+
+func TestCompositeRuleNextFileNameFallsBackWhenNoRuleFired(t *testing.T) {
+	daily := &stubRule{name: "daily"}
+	size := &stubRule{name: "size"}
+
+	composite := NewCompositeRule(daily, size)
+
+	if composite.ShallRotate(0) {
+		t.Fatal("expected ShallRotate to report no rotation is due")
+	}
+
+	if got := composite.NextFileName(); got != "daily" {
+		t.Fatalf("NextFileName() = %q, want %q (fallback to the first rule)", got, "daily")
+	}
+}
+
+func TestNewSizeRuleSeedsFromExistingBackups(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "app.log")
+
+	for _, n := range []int{1, 2, 5} {
+		name := base + "." + strconv.Itoa(n)
+		if err := os.WriteFile(name, []byte("old"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rule := NewSizeRule(base, 1024)
+
+	want := base + ".6"
+	if got := rule.NextFileName(); got != want {
+		t.Fatalf("NextFileName() = %q, want %q (after the highest existing backup)", got, want)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -43,8 +44,18 @@ func New(p string, options ...Option) (*RotateLogs, error) {
 	var rotationCount uint
 	var linkName string
 	var maxAge time.Duration
+	var maxAgeSet bool
+	var rotationCountSet bool
 	var handler Handler
 	var forceNewFile bool
+	var compressAlgorithm CompressAlgorithm
+	var compressAfter time.Duration
+	var rotateRule RotateRule
+	var purgeConcurrency int
+	var rotateMode RotateMode
+	var maxBackups uint
+	var asyncBuffer int
+	var dropPolicy DropPolicy
 
 	for _, o := range options {
 		switch o.Name() {
@@ -57,6 +68,7 @@ func New(p string, options ...Option) (*RotateLogs, error) {
 			if maxAge < 0 {
 				maxAge = 0
 			}
+			maxAgeSet = true
 		case optkeyRotationTime:
 			rotationTime = o.Value().(time.Duration)
 			if rotationTime < 0 {
@@ -69,10 +81,27 @@ func New(p string, options ...Option) (*RotateLogs, error) {
 			}
 		case optkeyRotationCount:
 			rotationCount = o.Value().(uint)
+			rotationCountSet = true
 		case optkeyHandler:
 			handler = o.Value().(Handler)
 		case optkeyForceNewFile:
 			forceNewFile = true
+		case optkeyCompress:
+			compressAlgorithm = o.Value().(CompressAlgorithm)
+		case optkeyCompressAfter:
+			compressAfter = o.Value().(time.Duration)
+		case optkeyRotateRule:
+			rotateRule = o.Value().(RotateRule)
+		case optkeyPurgeConcurrency:
+			purgeConcurrency = o.Value().(int)
+		case optkeyRotateMode:
+			rotateMode = o.Value().(RotateMode)
+		case optkeyMaxBackups:
+			maxBackups = o.Value().(uint)
+		case optkeyAsyncBuffer:
+			asyncBuffer = o.Value().(int)
+		case optkeyDropPolicy:
+			dropPolicy = o.Value().(DropPolicy)
 		}
 	}
 
@@ -80,12 +109,29 @@ func New(p string, options ...Option) (*RotateLogs, error) {
 		return nil, errors.New("options MaxAge and RotationCount cannot be both set")
 	}
 
+	if rotateRule != nil && (maxAgeSet || rotationCountSet) {
+		// A RotateRule owns purge policy entirely (via OutdatedFiles);
+		// silently honoring MaxAge/RotationCount on top of it would
+		// just as silently be wrong whenever the rule's own retention
+		// window differs, so reject the combination instead.
+		return nil, errors.New("options MaxAge and RotationCount cannot be used together with RotateRule")
+	}
+
 	if maxAge == 0 && rotationCount == 0 {
 		// if both are 0, give maxAge a sane default
 		maxAge = 7 * 24 * time.Hour
 	}
 
-	return &RotateLogs{
+	if rotateMode == ModeRename {
+		// p has no strftime tokens to turn into wildcards under
+		// ModeRename, so the pattern built above (just p, unchanged)
+		// would never match the numbered backups (p+".1", p+".2", ...)
+		// that shiftBackups produces. Purge and compression both rely
+		// on globPattern to find those files.
+		globPattern = p + ".*"
+	}
+
+	rl := &RotateLogs{
 		clock:         clock,
 		eventHandler:  handler,
 		globPattern:   globPattern,
@@ -96,7 +142,24 @@ func New(p string, options ...Option) (*RotateLogs, error) {
 		rotationSize:  rotationSize,
 		rotationCount: rotationCount,
 		forceNewFile:  forceNewFile,
-	}, nil
+
+		compressAlgorithm: compressAlgorithm,
+		compressAfter:     compressAfter,
+
+		rotateRule: rotateRule,
+
+		purgeConcurrency: purgeConcurrency,
+
+		rotateMode: rotateMode,
+		maxBackups: maxBackups,
+		activeFn:   p,
+	}
+
+	if asyncBuffer > 0 {
+		rl.async = newAsyncWriter(rl, asyncBuffer, dropPolicy)
+	}
+
+	return rl, nil
 }
 
 // Write satisfies the io.Writer interface. It writes to the
@@ -104,6 +167,19 @@ func New(p string, options ...Option) (*RotateLogs, error) {
 // If we have reached rotation time, the target file gets
 // automatically rotated, and also purged if necessary.
 func (rl *RotateLogs) Write(p []byte) (n int, err error) {
+	if rl.async != nil {
+		rl.async.write(p)
+		return len(p), nil
+	}
+
+	return rl.writeSync(p)
+}
+
+// writeSync performs the actual, synchronous write: acquire the
+// current (possibly freshly rotated) file and write p to it. In
+// asynchronous mode (WithAsyncBuffer), this runs on the background
+// drain goroutine instead of the caller's.
+func (rl *RotateLogs) writeSync(p []byte) (n int, err error) {
 	// Guard against concurrent writes
 	rl.mutex.Lock()
 	defer rl.mutex.Unlock()
@@ -116,8 +192,26 @@ func (rl *RotateLogs) Write(p []byte) (n int, err error) {
 	return out.Write(p)
 }
 
+// getWriterNolock picks the rotation strategy for this call: ModeRename,
+// then an explicit RotateRule (see getWriterNolockWithRule), and only then
+// the strftime/".rotation.N"/size logic below, which predates RotateRule
+// and still runs as-is whenever neither of those is configured. That
+// logic was never actually folded into RotateRule as an orchestrator --
+// WithRotateRule added a second, independent rotation-decision path
+// alongside it rather than replacing it, so the two must be kept in sync
+// by hand. Fully unifying them would mean rewriting this legacy branch in
+// terms of RotateRule, which is a larger change than fits here.
+//
 // must be locked during this operation
 func (rl *RotateLogs) getWriterNolock(bailOnRotateFail, useGenerationalNames bool) (io.Writer, error) {
+	if rl.rotateMode == ModeRename {
+		return rl.getWriterNolockRename(useGenerationalNames)
+	}
+
+	if rl.rotateRule != nil {
+		return rl.getWriterNolockWithRule(bailOnRotateFail, useGenerationalNames)
+	}
+
 	generation := rl.generation
 	previousFn := rl.curFn
 
@@ -261,6 +355,13 @@ func (rl *RotateLogs) getWriterNolock(bailOnRotateFail, useGenerationalNames boo
 		})
 	}
 
+	// previousFn is, as of this write, no longer the active file, so
+	// it is safe to hand off to the background compressor. The active
+	// file (filename) is never touched.
+	if previousFn != "" && previousFn != filename && rl.compressAlgorithm != CompressNone {
+		go rl.compress(previousFn)
+	}
+
 	return fh, nil
 }
 
@@ -273,6 +374,23 @@ func (rl *RotateLogs) CurrentFileName() string {
 	return rl.curFn
 }
 
+// Purge removes files that are outdated according to MaxAge or
+// RotationCount (or, when a RotateRule is set, according to its
+// OutdatedFiles), without rotating to a new file. It is normally
+// invoked automatically as a side effect of rotation, but can also be
+// called directly by callers that want to batch cleanup on their own
+// schedule (such as rotator.Manager).
+func (rl *RotateLogs) Purge() error {
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	if rl.curFn == "" {
+		return nil
+	}
+
+	return rl.rotateNolock(rl.curFn)
+}
+
 var patternConversionRegexps = []*regexp.Regexp{
 	regexp.MustCompile(`%[%+A-Za-z]`),
 	regexp.MustCompile(`\*+`),
@@ -361,92 +479,100 @@ func (rl *RotateLogs) rotateNolock(filename string) error {
 		}
 	}
 
-	if rl.maxAge <= 0 && rl.rotationCount <= 0 {
-		return errors.New("panic: maxAge and rotationCount are both set")
-	}
-
-	matches, err := filepath.Glob(rl.globPattern)
-	if err != nil {
-		return err
-	}
+	var toUnlink []string
 
-	cutoff := rl.clock.Now().Add(-1 * rl.maxAge)
-
-	// the linter tells me to pre allocate this...
-	toUnlink := make([]string, 0, len(matches))
-	for _, path := range matches {
-		// Ignore lock files
-		if strings.HasSuffix(path, "_lock") || strings.HasSuffix(path, "_symlink") {
-			continue
-		}
-
-		fi, err := os.Stat(path)
-		if err != nil {
-			continue
-		}
-
-		fl, err := os.Lstat(path)
-		if err != nil {
-			continue
+	if rl.rotateRule != nil {
+		// A registered RotateRule owns purge policy entirely, in place
+		// of the MaxAge/RotationCount logic below; its own notion of
+		// "outdated" is what decides what gets removed.
+		seen := make(map[string]struct{})
+		for _, pattern := range rl.purgeGlobPatterns() {
+			for _, path := range rl.rotateRule.OutdatedFiles(pattern) {
+				if _, ok := seen[path]; ok {
+					continue
+				}
+				seen[path] = struct{}{}
+				toUnlink = append(toUnlink, path)
+			}
 		}
-
-		if rl.maxAge > 0 && fi.ModTime().After(cutoff) {
-			continue
+	} else {
+		if rl.maxAge <= 0 && rl.rotationCount <= 0 {
+			return errors.New("panic: maxAge and rotationCount are both unset")
 		}
 
-		if rl.rotationCount > 0 && fl.Mode()&os.ModeSymlink == os.ModeSymlink {
-			continue
+		// The compressed-suffix pattern can overlap the plain one
+		// (e.g. "foo.*" and "foo.*.gz"), so drop repeated matches.
+		var matches []string
+		seen := make(map[string]struct{})
+		for _, pattern := range rl.purgeGlobPatterns() {
+			found, err := filepath.Glob(pattern)
+			if err != nil {
+				return err
+			}
+			for _, path := range found {
+				if _, ok := seen[path]; ok {
+					continue
+				}
+				seen[path] = struct{}{}
+				matches = append(matches, path)
+			}
 		}
-		toUnlink = append(toUnlink, path)
-	}
 
-	var toUnlinkfile string
-
-	if rl.rotationCount > 0 {
-		// Only delete if we have more than rotationCount
-		if rl.rotationCount >= uint(len(toUnlink)) {
-			return nil
-		}
+		cutoff := rl.clock.Now().Add(-1 * rl.maxAge)
 
-		// Delete the oldest file. Modified by chenyan
-		minFileTime := time.Now().Unix()
-		//fmt.Println("----minFileTime = ", minFileTime)
+		// the linter tells me to pre allocate this...
+		toUnlink = make([]string, 0, len(matches))
+		for _, path := range matches {
+			// Ignore lock files
+			if strings.HasSuffix(path, "_lock") || strings.HasSuffix(path, "_symlink") {
+				continue
+			}
 
-		for _, path := range toUnlink {
 			fi, err := os.Stat(path)
 			if err != nil {
 				continue
 			}
 
-			mtime := fi.ModTime().Unix()
-			//fmt.Println("===fi = ", path, fi.ModTime(), mtime)
+			fl, err := os.Lstat(path)
+			if err != nil {
+				continue
+			}
 
-			if mtime < minFileTime {
-				minFileTime = mtime
-				toUnlinkfile = path
-				//fmt.Println("toUnlinkfile = ", toUnlinkfile, minFileTime)
+			if rl.maxAge > 0 && fi.ModTime().After(cutoff) {
+				continue
 			}
 
-			//fmt.Println("minFileTime = ", minFileTime)
+			if rl.rotationCount > 0 && fl.Mode()&os.ModeSymlink == os.ModeSymlink {
+				continue
+			}
+			toUnlink = append(toUnlink, path)
 		}
 
-		//toUnlink = toUnlink[:len(toUnlink)-int(rl.rotationCount)]
-		////fmt.Println("===toUnlink = ", toUnlink)
+		if rl.rotationCount > 0 {
+			// Only the oldest files beyond rotationCount are purged;
+			// the rest are kept regardless of age.
+			if rl.rotationCount >= uint(len(toUnlink)) {
+				toUnlink = nil
+			} else {
+				sort.Slice(toUnlink, func(i, j int) bool {
+					fi, erri := os.Stat(toUnlink[i])
+					fj, errj := os.Stat(toUnlink[j])
+					if erri != nil || errj != nil {
+						return erri == nil
+					}
+					return fi.ModTime().Before(fj.ModTime())
+				})
+				toUnlink = toUnlink[:len(toUnlink)-int(rl.rotationCount)]
+			}
+		}
 	}
 
-	//fmt.Println("toUnlinkfile = ", toUnlinkfile)
-
-	//if len(toUnlink) <= 0 {
-	//	return nil
-	//}
+	if len(toUnlink) == 0 {
+		return nil
+	}
 
 	guard.Enable()
-	go func() {
-		// unlink files on a separate goroutine
-		//for _, path := range toUnlink {
-		os.Remove(toUnlinkfile)
-		//}
-	}()
+	go rl.purgeFiles(toUnlink)
 
 	return nil
 }
@@ -455,6 +581,10 @@ func (rl *RotateLogs) rotateNolock(filename string) error {
 // call this method if you performed any writes to
 // the object.
 func (rl *RotateLogs) Close() error {
+	if rl.async != nil {
+		rl.async.close()
+	}
+
 	rl.mutex.Lock()
 	defer rl.mutex.Unlock()
 
@@ -0,0 +1,42 @@
+package rotatelogs
+
+import (
+	"os"
+	"sync"
+	"time"
+
+	strftime "github.com/lestrrat-go/strftime"
+)
+
+// RotateLogs represents a log file that gets automatically rotated
+// as you write to it.
+type RotateLogs struct {
+	clock         Clock
+	curFn         string
+	curBaseFn     string
+	generation    int
+	globPattern   string
+	eventHandler  Handler
+	linkName      string
+	maxAge        time.Duration
+	mutex         sync.RWMutex
+	outFh         *os.File
+	pattern       *strftime.Strftime
+	rotationTime  time.Duration
+	rotationSize  int64
+	rotationCount uint
+	forceNewFile  bool
+
+	compressAlgorithm CompressAlgorithm
+	compressAfter     time.Duration
+
+	rotateRule RotateRule
+
+	purgeConcurrency int
+
+	rotateMode RotateMode
+	maxBackups uint
+	activeFn   string
+
+	async *asyncWriter
+}
@@ -0,0 +1,127 @@
+// Package rotator provides a Manager that keeps several named
+// *rotatelogs.RotateLogs streams (e.g. one per severity level or
+// subsystem) and runs a single cleanup goroutine that calls Purge on
+// every stream at a fixed interval. Each stream still uses the clock
+// given in its own options, and still purges as part of its own
+// rotation as well.
+package rotator
+
+import (
+	"sync"
+	"time"
+
+	rotatelogs "github.com/etsme-com/file-rotatelogs"
+)
+
+// Manager owns a set of named *rotatelogs.RotateLogs streams and
+// coordinates their cleanup on a shared ticker.
+type Manager struct {
+	mutex   sync.RWMutex
+	streams map[string]*rotatelogs.RotateLogs
+
+	cleanupInterval time.Duration
+	closeCh         chan struct{}
+	closeOnce       sync.Once
+}
+
+// New creates a Manager whose cleanup goroutine runs every interval.
+// If interval is 0, it defaults to one minute.
+func New(interval time.Duration) *Manager {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+
+	m := &Manager{
+		streams:         make(map[string]*rotatelogs.RotateLogs),
+		cleanupInterval: interval,
+		closeCh:         make(chan struct{}),
+	}
+
+	go m.cleanupLoop()
+
+	return m
+}
+
+// Open returns the *rotatelogs.RotateLogs registered under name,
+// creating it with pattern and opts the first time name is seen.
+// Subsequent calls with the same name ignore pattern and opts and
+// return the existing stream.
+func (m *Manager) Open(name, pattern string, opts ...rotatelogs.Option) (*rotatelogs.RotateLogs, error) {
+	m.mutex.RLock()
+	rl, ok := m.streams[name]
+	m.mutex.RUnlock()
+	if ok {
+		return rl, nil
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if rl, ok := m.streams[name]; ok {
+		return rl, nil
+	}
+
+	rl, err := rotatelogs.New(pattern, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	m.streams[name] = rl
+	return rl, nil
+}
+
+// Reopen calls Rotate on every registered stream. It is suitable for
+// use in a SIGHUP handler, to force all streams onto a fresh file at
+// once.
+func (m *Manager) Reopen() error {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	var firstErr error
+	for _, rl := range m.streams {
+		if err := rl.Rotate(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// CloseAll closes every registered stream and stops the cleanup
+// goroutine.
+func (m *Manager) CloseAll() error {
+	m.closeOnce.Do(func() { close(m.closeCh) })
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	var firstErr error
+	for _, rl := range m.streams {
+		if err := rl.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+func (m *Manager) cleanupLoop() {
+	ticker := time.NewTicker(m.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.cleanup()
+		case <-m.closeCh:
+			return
+		}
+	}
+}
+
+func (m *Manager) cleanup() {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, rl := range m.streams {
+		rl.Purge()
+	}
+}